@@ -0,0 +1,149 @@
+package netint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheTTL is how long a cached samples response is reused by
+// GetCachedOverview before it's fetched again from Linode.
+var CacheTTL = time.Minute
+
+// cacheEntry is the on-disk format of a single cached samples response
+type cacheEntry struct {
+	SchemaVersion int             `json:"schema_version"`
+	Fetched       time.Time       `json:"fetched"`
+	Body          json.RawMessage `json:"body"`
+}
+
+// CacheDir is a function to get the directory cached responses are stored
+// in. This is the "linode-netint" directory within the user's cache
+// directory ($XDG_CACHE_HOME or ~/.cache on Linux).
+func CacheDir() (string, error) {
+	d, err := os.UserCacheDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(d, "linode-netint"), nil
+}
+
+// AllCachedOverviews is the same as AllOverviews, except that each region
+// is obtained using GetCachedOverview.
+func AllCachedOverviews() (map[string]*Overview, error) {
+	return allOverviews(GetCachedOverview)
+}
+
+// GetCachedOverview is the same as GetOverview, except that the response
+// from Linode is cached to disk within CacheDir(). If the cached response is
+// younger than CacheTTL it's used instead of making a new request. This is
+// meant for short-lived programs, like command line tools, that would
+// otherwise make the same requests on each invocation. Failing to write the
+// cache is not considered an error.
+func GetCachedOverview(dc string) (*Overview, error) {
+	u, err := overviewURL(dc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := CacheDir()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fn := filepath.Join(dir, dc+".json")
+
+	// a cached body that doesn't parse is treated as a miss
+	if body, ok := readCache(fn); ok {
+		if o, err := parseOverview(dc, body); err == nil {
+			return o, nil
+		}
+	}
+
+	body, err := responseBody(u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := parseOverview(dc, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	writeCache(dir, fn, body)
+
+	return o, nil
+}
+
+// readCache returns the cached body in 'fn' if it exists and hasn't expired
+func readCache(fn string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(fn)
+
+	if err != nil {
+		return nil, false
+	}
+
+	// entries written by a newer version of
+	// this package are treated as a cache miss
+	data, err = migrate(data, cacheMigrations)
+
+	if err != nil {
+		return nil, false
+	}
+
+	e := &cacheEntry{}
+
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, false
+	}
+
+	// an entry from the future (e.g., clock skew) is a miss
+	if age := now().Sub(e.Fetched); age < 0 || age > CacheTTL {
+		return nil, false
+	}
+
+	return e.Body, true
+}
+
+// writeCache stores 'body' in 'fn'. The entry is written to a temporary file
+// first and renamed so concurrent readers never see a partial entry.
+func writeCache(dir, fn string, body []byte) {
+	data, err := json.Marshal(&cacheEntry{SchemaVersion: SchemaVersion, Fetched: now(), Body: body})
+
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	f, err := ioutil.TempFile(dir, ".tmp-")
+
+	if err != nil {
+		return
+	}
+
+	_, err = f.Write(data)
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return
+	}
+
+	if err := os.Rename(f.Name(), fn); err != nil {
+		os.Remove(f.Name())
+	}
+}
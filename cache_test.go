@@ -0,0 +1,115 @@
+package netint
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCacheTTL(t *testing.T) {
+	fetched := time.Unix(1418700000, 0)
+	at := fetched
+	withClock(t, &at)
+
+	dir := t.TempDir()
+	fn := filepath.Join(dir, "dallas.json")
+
+	writeCache(dir, fn, []byte(`{}`))
+
+	tests := []struct {
+		name string
+		age  time.Duration
+		hit  bool
+	}{
+		{"just fetched", 0, true},
+		{"within the TTL", CacheTTL - time.Second, true},
+		{"at the TTL", CacheTTL, true},
+		{"expired", CacheTTL + time.Second, false},
+		{"fetched in the future", -time.Second, false},
+	}
+
+	for _, tt := range tests {
+		at = fetched.Add(tt.age)
+
+		if _, ok := readCache(fn); ok != tt.hit {
+			t.Errorf("%v: cache hit = %v, want %v", tt.name, ok, tt.hit)
+		}
+	}
+}
+
+// useCacheDir points CacheDir at a temporary directory, skipping the test
+// on platforms where $XDG_CACHE_HOME isn't used
+func useCacheDir(t *testing.T) string {
+	d := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", d)
+
+	dir, err := CacheDir()
+
+	if err != nil || !strings.HasPrefix(dir, d) {
+		t.Skip("CacheDir doesn't use $XDG_CACHE_HOME on this platform")
+	}
+
+	return dir
+}
+
+func TestGetCachedOverview(t *testing.T) {
+	useCacheDir(t)
+	up := withUpstream(t)
+
+	at := time.Unix(1418700000, 0)
+	withClock(t, &at)
+
+	get := func(requests int) {
+		t.Helper()
+
+		o, err := GetCachedOverview("dallas")
+
+		if err != nil || o.Dallas == nil || o.Name != "dallas" {
+			t.Fatalf("got %+v, %v", o, err)
+		}
+
+		if up.requests != requests {
+			t.Errorf("made %d requests, want %d", up.requests, requests)
+		}
+	}
+
+	get(1)
+
+	// served from the cache
+	at = at.Add(CacheTTL / 2)
+	get(1)
+
+	// expired
+	at = at.Add(CacheTTL)
+	get(2)
+}
+
+func TestGetCachedOverviewBadBody(t *testing.T) {
+	dir := useCacheDir(t)
+	up := withUpstream(t)
+
+	at := time.Unix(1418700000, 0)
+	withClock(t, &at)
+
+	// a fresh entry whose body isn't a samples object
+	writeCache(dir, filepath.Join(dir, "dallas.json"), []byte(`[]`))
+
+	o, err := GetCachedOverview("dallas")
+
+	if err != nil || o.Dallas == nil {
+		t.Fatalf("got %+v, %v", o, err)
+	}
+
+	if up.requests != 1 {
+		t.Errorf("made %d requests, want 1", up.requests)
+	}
+
+	// the refetched response replaced the bad entry
+	data, err := ioutil.ReadFile(filepath.Join(dir, "dallas.json"))
+
+	if err != nil || !strings.Contains(string(data), "linode-dallas") {
+		t.Errorf("cache entry not rewritten: %s %v", data, err)
+	}
+}
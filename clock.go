@@ -0,0 +1,52 @@
+package netint
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of the current time for this package. It's used for
+// cache expiry and the timestamps of generated payloads, and can be replaced
+// using WithClock to make that behavior deterministic.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to allow the use of an ordinary function as a
+// Clock.
+type ClockFunc func() time.Time
+
+// Now calls f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+var (
+	clockMu sync.RWMutex
+	clock   Clock = ClockFunc(time.Now)
+)
+
+// WithClock is a function to set the Clock used by this package. It returns
+// the previous Clock so that it can be restored. If 'c' is nil the system
+// clock is used.
+func WithClock(c Clock) Clock {
+	if c == nil {
+		c = ClockFunc(time.Now)
+	}
+
+	clockMu.Lock()
+	defer clockMu.Unlock()
+
+	prev := clock
+	clock = c
+
+	return prev
+}
+
+// now returns the current time according to the package Clock
+func now() time.Time {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+
+	return clock.Now()
+}
@@ -0,0 +1,36 @@
+package netint
+
+import (
+	"testing"
+	"time"
+)
+
+// withClock makes the package Clock report '*t' for the rest of the test.
+// Changing '*t' moves the clock.
+func withClock(tb testing.TB, t *time.Time) {
+	prev := WithClock(ClockFunc(func() time.Time { return *t }))
+	tb.Cleanup(func() { WithClock(prev) })
+}
+
+func TestWithClock(t *testing.T) {
+	at := time.Unix(1418700000, 0)
+	withClock(t, &at)
+
+	if got := now(); !got.Equal(at) {
+		t.Errorf("now() = %v, want %v", got, at)
+	}
+
+	at = at.Add(time.Minute)
+
+	if got := now(); !got.Equal(at) {
+		t.Errorf("now() = %v after moving the clock, want %v", got, at)
+	}
+
+	// nil restores the system clock
+	prev := WithClock(nil)
+	defer WithClock(prev)
+
+	if got := now(); got.Year() < 2015 {
+		t.Errorf("now() = %v with the system clock", got)
+	}
+}
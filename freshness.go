@@ -0,0 +1,82 @@
+package netint
+
+import "time"
+
+// FreshnessLag is how far a region's newest sample may lag behind the
+// freshest region before FreshnessReport flags it as stale.
+var FreshnessLag = 10 * time.Minute
+
+// Freshness is how up to date the samples reported by a single region are.
+type Freshness struct {
+	Name string
+
+	// Newest is the time of the region's newest sample. It's the zero
+	// time if the region has no samples.
+	Newest time.Time
+
+	// Age is how long ago Newest was
+	Age time.Duration
+
+	// Lag is how far Newest is behind the newest sample of any region
+	Lag time.Duration
+
+	// Stale is true if Lag exceeds FreshnessLag, or the region has no
+	// samples
+	Stale bool
+}
+
+// FreshnessReport is a function to report how up to date each region's
+// samples are, with 'all' being the overviews keyed by region name as
+// returned by AllOverviews. The report has an entry for every region, in
+// the same order as Regions(). Regions missing from 'all' are stale.
+func FreshnessReport(all map[string]*Overview) []*Freshness {
+	var report []*Freshness
+	var freshest time.Time
+
+	for _, r := range Regions() {
+		f := &Freshness{Name: r}
+
+		if o := all[r]; o != nil {
+			f.Newest = o.newest()
+		}
+
+		if f.Newest.After(freshest) {
+			freshest = f.Newest
+		}
+
+		report = append(report, f)
+	}
+
+	t := now()
+
+	for _, f := range report {
+		if f.Newest.IsZero() {
+			f.Stale = true
+			continue
+		}
+
+		f.Age = t.Sub(f.Newest)
+		f.Lag = freshest.Sub(f.Newest)
+		f.Stale = f.Lag > FreshnessLag
+	}
+
+	return report
+}
+
+// newest returns the time of the newest sample in the overview, or the
+// zero time if it has none
+func (o *Overview) newest() time.Time {
+	var newest int64
+
+	for _, r := range Regions() {
+		if s := o.Sample(r); s != nil && s.Epoch > newest {
+			newest = s.Epoch
+		}
+	}
+
+	if newest == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(newest, 0)
+}
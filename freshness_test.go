@@ -0,0 +1,79 @@
+package netint
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreshnessReport(t *testing.T) {
+	base := time.Unix(1418700000, 0)
+
+	at := base.Add(2 * time.Minute)
+	withClock(t, &at)
+
+	// newest sample time of each region that has samples
+	newest := map[string]time.Time{
+		"dallas":  base,
+		"fremont": base.Add(-time.Minute),
+		"atlanta": base.Add(-FreshnessLag),
+		"newark":  base.Add(-FreshnessLag - time.Second),
+		"london":  base.Add(-time.Hour),
+	}
+
+	all := testOverviews(func(from, to string) *Sample {
+		n, ok := newest[from]
+
+		if !ok {
+			return nil
+		}
+
+		s := healthy()
+		s.Epoch = n.Unix()
+
+		// an older sample for another link doesn't change the newest
+		if to == "tokyo" {
+			s.Epoch -= 3600
+		}
+
+		return s
+	})
+
+	report := FreshnessReport(all)
+
+	if len(report) != len(Regions()) {
+		t.Fatalf("got %d entries, want %d", len(report), len(Regions()))
+	}
+
+	want := map[string]struct {
+		age, lag time.Duration
+		stale    bool
+	}{
+		"dallas":  {2 * time.Minute, 0, false},
+		"fremont": {3 * time.Minute, time.Minute, false},
+		"atlanta": {2*time.Minute + FreshnessLag, FreshnessLag, false},
+		"newark":  {2*time.Minute + FreshnessLag + time.Second, FreshnessLag + time.Second, true},
+		"london":  {time.Hour + 2*time.Minute, time.Hour, true},
+	}
+
+	for i, f := range report {
+		if f.Name != Regions()[i] {
+			t.Errorf("entry %d is %v, want %v", i, f.Name, Regions()[i])
+		}
+
+		// tokyo has no samples at all
+		if f.Name == "tokyo" {
+			if !f.Stale || !f.Newest.IsZero() || f.Age != 0 || f.Lag != 0 {
+				t.Errorf("tokyo: got %+v, want a stale entry without samples", *f)
+			}
+
+			continue
+		}
+
+		w := want[f.Name]
+
+		if !f.Newest.Equal(newest[f.Name]) || f.Age != w.age || f.Lag != w.lag || f.Stale != w.stale {
+			t.Errorf("%v: got newest %v, age %v, lag %v, stale %v; want %v, %v, %v, %v",
+				f.Name, f.Newest, f.Age, f.Lag, f.Stale, newest[f.Name], w.age, w.lag, w.stale)
+		}
+	}
+}
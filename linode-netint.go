@@ -4,11 +4,11 @@
 // some alterations to the data provided by Linode as some of the JSON types
 // don't make sense...
 //
-// * The rount-trip-time (RTT) field is converted from a string to uint32
+// * The rount-trip-time (RTT) field is converted from a string to Milliseconds
 //
-// * The Loss field is converted from a string to uint32
+// * The Loss field is converted from a string to Percent
 //
-// * The Jitter field is converted from a string to a uint32
+// * The Jitter field is converted from a string to Milliseconds
 //
 // To note, this package is not maintained by nor affiliated with Linode. It
 // simply consumes data from an undocumented pulic API.
@@ -20,18 +20,28 @@ import (
 	"io/ioutil"
 	"net/http"
 	"runtime"
-	"strconv"
+	"sort"
+	"strings"
 )
 
 const (
+	// HostFormat is the hostname of a datacenter's netint
+	// endpoint with a format specifier for the datacenter's
+	// abbreviation
+	HostFormat = "netint-%v.linode.com"
+
 	// BaseURL is the base URL with a format specifier
 	// for the datacenter's abbreviation
-	BaseURL = "http://netint-%v.linode.com/ping/samples"
+	BaseURL = "http://" + HostFormat + "/ping/samples"
 
 	// Version is the version, man...
 	Version = "0.0.2"
 )
 
+// HTTPClient is the *http.Client used for all requests to Linode. It may be
+// replaced to change timeouts or the transport (e.g., in tests).
+var HTTPClient = &http.Client{}
+
 type dc struct {
 	name string
 	abbr string
@@ -48,28 +58,31 @@ var datacenters = struct {
 }{
 	&dc{name: "dallas", abbr: "dal"},
 	&dc{name: "fremont", abbr: "fmt"},
-	&dc{name: "atlant", abbr: "atl"},
+	&dc{name: "atlanta", abbr: "atl"},
 	&dc{name: "newark", abbr: "nwk"},
 	&dc{name: "london", abbr: "lon"},
 	&dc{name: "tokyo", abbr: "tok"},
 }
 
-// used for parsing the JSON response
-type samples struct {
-	Dallas  [][]interface{} `json:"linode-dallas"`
-	Fremont [][]interface{} `json:"linode-fremont"`
-	Atlanta [][]interface{} `json:"linode-atlanta"`
-	Newark  [][]interface{} `json:"linode-newark"`
-	London  [][]interface{} `json:"linode-london"`
-	Tokyo   [][]interface{} `json:"linode-tokyo"`
-}
-
 // Sample is a single result for a point-to-point measurement.
+//
+// Linode doesn't always report every measurement. The HasRTT, HasLoss, and
+// HasJitter fields are false when the corresponding value was missing or
+// empty in the response, in which case the value itself is zero and should
+// not be treated as a measurement.
 type Sample struct {
 	Epoch  int64
-	RTT    uint32 // unit: milliseconds
-	Loss   uint32 // unit: percentage
-	Jitter uint32 // unit: milliseconds
+	RTT    Milliseconds
+	Loss   Percent
+	Jitter Milliseconds
+
+	HasRTT    bool
+	HasLoss   bool
+	HasJitter bool
+
+	// Previous is the sample Linode provided before this one, or nil if
+	// there wasn't one. The Previous of a Previous sample is always nil.
+	Previous *Sample
 }
 
 // Overview is the entire view a single region has to the rest of the regions.
@@ -82,6 +95,22 @@ type Overview struct {
 	Newark  *Sample
 	London  *Sample
 	Tokyo   *Sample
+
+	// Warnings are the non-fatal issues found while parsing the
+	// response. A region with a malformed sample has a nil *Sample
+	// and a corresponding Warning.
+	Warnings []Warning
+}
+
+// Warning is a non-fatal issue found while parsing the samples
+// provided by Linode.
+type Warning struct {
+	Region  string // the region the issue relates to (e.g., "dallas")
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%v: %v", w.Region, w.Message)
 }
 
 // Regions is a function that returns a slice of strings that is the
@@ -119,16 +148,46 @@ func Abbr(dc string) string {
 	}
 }
 
+// HostForRegion is a function to get the hostname of the netint endpoint
+// for a datacenter. 'dc' is the full name of the datacenter (e.g.,
+// "dallas"). Returns an error if given an unknown datacenter.
+func HostForRegion(dc string) (string, error) {
+	dcAbbr := Abbr(dc)
+
+	if dcAbbr == "" {
+		return "", fmt.Errorf("'%v' is not a valid datacenter\n", dc)
+	}
+
+	return fmt.Sprintf(HostFormat, dcAbbr), nil
+}
+
+// Hosts is a function that returns the hostnames of the netint endpoints
+// for all regions, in the same order as Regions().
+func Hosts() []string {
+	var h []string
+
+	for _, r := range Regions() {
+		host, _ := HostForRegion(r)
+		h = append(h, host)
+	}
+
+	return h
+}
+
 // AllOverviews is a function to return all overviews.
 // It's a map of *Overview instances with the lowercase name
 // of the region as the key.
 func AllOverviews() (map[string]*Overview, error) {
+	return allOverviews(GetOverview)
+}
+
+func allOverviews(get func(string) (*Overview, error)) (map[string]*Overview, error) {
 	m := make(map[string]*Overview)
 
 	// loop over each region and
 	// populate its overview
 	for _, d := range Regions() {
-		o, err := GetOverview(d)
+		o, err := get(d)
 
 		if err != nil {
 			return nil, err
@@ -171,52 +230,60 @@ func Tokyo() (*Overview, error) {
 }
 
 // GetOverview is a function to get an overview of a single datacenter with
-// 'dc' being the datacenter name (e.g., "dallas")
+// 'dc' being the datacenter name (e.g., "dallas"). An error is only returned
+// if the request fails or the response isn't a JSON object. A missing or
+// malformed sample for a region leaves that region's *Sample nil and adds a
+// Warning to the Overview instead, so check each *Sample before using it.
 func GetOverview(dc string) (o *Overview, err error) {
-	var u string
+	u, err := overviewURL(dc)
+
+	if err != nil {
+		return
+	}
 
+	body, err := responseBody(u)
+
+	if err != nil {
+		return
+	}
+
+	return parseOverview(dc, body)
+}
+
+// overviewURL returns the samples URL for the datacenter 'dc'
+func overviewURL(dc string) (string, error) {
 	// determine the URL based on the region
 	// if the region is unknown return error
 	switch dc {
 	case "testdatacenter":
 		// for testing purposes only
-		u = "http://www.mocky.io/v2/548fd4750b9c75fd02437812"
+		return "http://www.mocky.io/v2/548fd4750b9c75fd02437812", nil
 	default:
 		dcAbbr := Abbr(dc)
 		if dcAbbr == "" {
-			return nil, fmt.Errorf("'%v' is not a valid datacenter\n", dc)
+			return "", fmt.Errorf("'%v' is not a valid datacenter\n", dc)
 		}
-		u = fmt.Sprintf(BaseURL, dcAbbr)
-	}
-
-	body, err := responseBody(u)
-
-	if err != nil {
-		return
+		return fmt.Sprintf(BaseURL, dcAbbr), nil
 	}
+}
 
-	s := &samples{}
+// parseOverview builds the *Overview for 'dc' from a raw samples body
+func parseOverview(dc string, body []byte) (o *Overview, err error) {
+	s := samples{}
 
-	err = json.Unmarshal(body, s)
+	err = json.Unmarshal(body, &s)
 
 	if err != nil {
 		return
 	}
 
-	o, err = buildOverview(s)
-
-	if err != nil {
-		return nil, err
-	}
-
+	o = buildOverview(s)
 	o.Name = dc
 
 	return
 }
 
 func responseBody(url string) ([]byte, error) {
-	httpc := &http.Client{}
-
 	req, err := http.NewRequest("GET", url, nil)
 
 	if err != nil {
@@ -228,7 +295,7 @@ func responseBody(url string) ([]byte, error) {
 	req.Header.Add("User-Agent", fmt.Sprintf("LinodeNetInt/%v (%v net/http)", Version, runtime.Version()))
 
 	// execute the request
-	resp, err := httpc.Do(req)
+	resp, err := HTTPClient.Do(req)
 
 	if err != nil {
 		return nil, err
@@ -236,6 +303,10 @@ func responseBody(url string) ([]byte, error) {
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status from %v: %v", url, resp.Status)
+	}
+
 	// get the entire body
 	body, err := ioutil.ReadAll(resp.Body)
 
@@ -246,83 +317,67 @@ func responseBody(url string) ([]byte, error) {
 	return body, nil
 }
 
-func buildOverview(s *samples) (o *Overview, err error) {
-	o = &Overview{}
+func buildOverview(s samples) *Overview {
+	o := &Overview{}
 
-	o.Dallas, err = pullSample(s.Dallas)
+	for _, r := range Regions() {
+		rs, ok := s["linode-"+r]
 
-	if err != nil {
-		return nil, err
-	}
-
-	o.Fremont, err = pullSample(s.Fremont)
-
-	if err != nil {
-		return nil, err
-	}
-
-	o.Atlanta, err = pullSample(s.Atlanta)
-
-	if err != nil {
-		return nil, err
-	}
+		if !ok {
+			o.warn(r, "no samples provided")
+			continue
+		}
 
-	o.Newark, err = pullSample(s.Newark)
+		if rs.err != nil {
+			o.warn(r, rs.err.Error())
+			continue
+		}
 
-	if err != nil {
-		return nil, err
+		*o.field(r) = rs.sample
 	}
 
-	o.London, err = pullSample(s.London)
+	// flag any regions Linode has that we don't know about,
+	// as well as any keys that aren't regions at all
+	var keys []string
 
-	if err != nil {
-		return nil, err
+	for k := range s {
+		keys = append(keys, k)
 	}
 
-	o.Tokyo, err = pullSample(s.Tokyo)
+	sort.Strings(keys)
 
-	if err != nil {
-		return nil, err
+	for _, k := range keys {
+		switch r := strings.TrimPrefix(k, "linode-"); {
+		case !strings.HasPrefix(k, "linode-"):
+			o.warn(k, "unexpected key")
+		case Abbr(r) == "":
+			o.warn(r, "unknown region")
+		}
 	}
 
-	return
+	return o
 }
 
-func pullSample(i [][]interface{}) (s *Sample, err error) {
-	// NOTE: As has been historically been a pain point with Linode,
-	//       these endpoints provide some wonky JSON. Only the timestamp
-	//       is in a useful format (numeric). RTT, Loss, and Jitter are all
-	//       strings for some reason. So we need to get those values.
-
-	// convert the RTT to a uint
-	r, err := strconv.ParseUint(i[0][1].(string), 10, 32)
-
-	if err != nil {
-		return
-	}
-
-	// convert the Loss to a uint
-	l, err := strconv.ParseUint(i[0][2].(string), 10, 32)
-
-	if err != nil {
-		return
-	}
-
-	// convert the jitter to a uint
-	j, err := strconv.ParseUint(i[0][3].(string), 10, 32)
-
-	if err != nil {
-		return
+// field returns a pointer to the *Sample field for 'region'
+func (o *Overview) field(region string) **Sample {
+	switch region {
+	case datacenters.dallas.name:
+		return &o.Dallas
+	case datacenters.fremont.name:
+		return &o.Fremont
+	case datacenters.atlanta.name:
+		return &o.Atlanta
+	case datacenters.newark.name:
+		return &o.Newark
+	case datacenters.london.name:
+		return &o.London
+	case datacenters.tokyo.name:
+		return &o.Tokyo
+	default:
+		return nil
 	}
+}
 
-	s = &Sample{}
-
-	// convert the UNIX timestamp to an int64
-	s.Epoch = int64(i[0][0].(float64))
-
-	s.RTT = uint32(r)
-	s.Loss = uint32(l)
-	s.Jitter = uint32(j)
-
-	return
+func (o *Overview) warn(region, msg string) {
+	o.Warnings = append(o.Warnings, Warning{Region: region, Message: msg})
 }
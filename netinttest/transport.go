@@ -0,0 +1,199 @@
+// Package netinttest provides utilities for testing code that uses the
+// netint package against a misbehaving upstream. To use the Transport,
+// replace the netint HTTP client:
+//
+//	netint.HTTPClient = &http.Client{
+//		Transport: &netinttest.Transport{
+//			Schedule: []netinttest.Fault{netinttest.None, netinttest.ServerError},
+//		},
+//	}
+package netinttest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault is a kind of upstream misbehavior the Transport can inject.
+type Fault int
+
+const (
+	// None passes the request through untouched
+	None Fault = iota
+
+	// Latency delays the request by Transport.Latency before
+	// passing it through
+	Latency
+
+	// Timeout blocks until Transport.Timeout elapses and then fails with
+	// a timeout error. If the request is canceled first, the context's
+	// error is returned instead.
+	Timeout
+
+	// ServerError responds with a 503 Service Unavailable
+	ServerError
+
+	// TruncatedBody passes the request through but only returns the
+	// first half of the response body
+	TruncatedBody
+
+	// MalformedJSON responds with a 200 OK and a body that isn't valid JSON
+	MalformedJSON
+)
+
+// malformedBody is the body used for the MalformedJSON fault
+const malformedBody = `{"linode-dallas":[[1418700000,"1","0"`
+
+func (f Fault) String() string {
+	switch f {
+	case None:
+		return "None"
+	case Latency:
+		return "Latency"
+	case Timeout:
+		return "Timeout"
+	case ServerError:
+		return "ServerError"
+	case TruncatedBody:
+		return "TruncatedBody"
+	case MalformedJSON:
+		return "MalformedJSON"
+	default:
+		return "Fault(unknown)"
+	}
+}
+
+// Transport is an http.RoundTripper that injects faults into requests. The
+// n-th request made through the Transport gets the fault
+// Schedule[n % len(Schedule)], so the schedule repeats. An empty Schedule
+// never injects faults. It's safe for concurrent use.
+type Transport struct {
+	// Base is the http.RoundTripper requests are passed through to. If
+	// nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	Schedule []Fault
+
+	// Latency is the delay added by the Latency fault
+	Latency time.Duration
+
+	// Timeout is the longest the Timeout fault blocks for. If zero, it
+	// blocks until the request is canceled.
+	Timeout time.Duration
+
+	mu sync.Mutex
+	n  int
+}
+
+// timeoutError is returned for the Timeout fault. It satisfies net.Error.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "netinttest: injected timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// Requests returns the number of requests made through the Transport.
+func (t *Transport) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.n
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch t.next() {
+	case Latency:
+		select {
+		case <-time.After(t.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	case Timeout:
+		var after <-chan time.Time
+
+		if t.Timeout > 0 {
+			after = time.After(t.Timeout)
+		}
+
+		select {
+		case <-after:
+			return nil, timeoutError{}
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	case ServerError:
+		return response(req, http.StatusServiceUnavailable, "service unavailable\n"), nil
+	case MalformedJSON:
+		return response(req, http.StatusOK, malformedBody), nil
+	case TruncatedBody:
+		return t.truncated(req)
+	}
+
+	return t.base().RoundTrip(req)
+}
+
+// next returns the fault for the next request
+func (t *Transport) next() Fault {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := t.n
+	t.n++
+
+	if len(t.Schedule) == 0 {
+		return None
+	}
+
+	return t.Schedule[n%len(t.Schedule)]
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+// truncated passes 'req' through and cuts the response body in half
+func (t *Transport) truncated(req *http.Request) (*http.Response, error) {
+	resp, err := t.base().RoundTrip(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body[:len(body)/2]))
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
+
+// response builds a synthetic *http.Response to 'req'
+func response(req *http.Request, code int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
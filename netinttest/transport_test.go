@@ -0,0 +1,194 @@
+package netinttest
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testBody = `{"linode-dallas":[[1418700000,"1","0","0"]]}`
+
+func newServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testBody))
+	}))
+}
+
+// get makes a request through 't', returning the status code and body
+func get(ctx context.Context, t *Transport, url string) (int, []byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	c := &http.Client{Transport: t}
+
+	resp, err := c.Do(req.WithContext(ctx))
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	return resp.StatusCode, body, err
+}
+
+func TestTransportNone(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	for _, tr := range []*Transport{{}, {Schedule: []Fault{None}}} {
+		code, body, err := get(context.Background(), tr, srv.URL)
+
+		if err != nil || code != http.StatusOK || string(body) != testBody {
+			t.Errorf("got %d %q %v, want the upstream response", code, body, err)
+		}
+	}
+}
+
+func TestTransportLatency(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	tr := &Transport{Schedule: []Fault{Latency}, Latency: 50 * time.Millisecond}
+
+	start := time.Now()
+	code, body, err := get(context.Background(), tr, srv.URL)
+
+	if err != nil || code != http.StatusOK || string(body) != testBody {
+		t.Errorf("got %d %q %v, want the upstream response", code, body, err)
+	}
+
+	if d := time.Since(start); d < tr.Latency {
+		t.Errorf("request took %v, want at least %v", d, tr.Latency)
+	}
+
+	// a canceled request doesn't wait out the latency
+	tr.Latency = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := get(ctx, tr, srv.URL); !isContextError(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestTransportTimeout(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	tr := &Transport{Schedule: []Fault{Timeout}, Timeout: 10 * time.Millisecond}
+
+	_, _, err := get(context.Background(), tr, srv.URL)
+
+	if ne, ok := unwrap(err).(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("error = %v, want a net.Error timeout", err)
+	}
+
+	// without a Timeout it blocks until the request is canceled, and
+	// returns the context's error rather than its own
+	tr.Timeout = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	if _, _, err := get(ctx, tr, srv.URL); !isContextError(err, context.Canceled) {
+		t.Errorf("error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTransportServerError(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	tr := &Transport{Schedule: []Fault{ServerError}}
+
+	code, _, err := get(context.Background(), tr, srv.URL)
+
+	if err != nil || code != http.StatusServiceUnavailable {
+		t.Errorf("got %d %v, want %d", code, err, http.StatusServiceUnavailable)
+	}
+}
+
+func TestTransportTruncatedBody(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	tr := &Transport{Schedule: []Fault{TruncatedBody}}
+
+	code, body, err := get(context.Background(), tr, srv.URL)
+
+	if err != nil || code != http.StatusOK {
+		t.Fatalf("got %d %v, want %d", code, err, http.StatusOK)
+	}
+
+	if want := testBody[:len(testBody)/2]; string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestTransportMalformedJSON(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	tr := &Transport{Schedule: []Fault{MalformedJSON}}
+
+	code, body, err := get(context.Background(), tr, srv.URL)
+
+	if err != nil || code != http.StatusOK {
+		t.Fatalf("got %d %v, want %d", code, err, http.StatusOK)
+	}
+
+	if json.Valid(body) {
+		t.Errorf("body %q is valid JSON", body)
+	}
+}
+
+func TestTransportScheduleWraps(t *testing.T) {
+	srv := newServer()
+	defer srv.Close()
+
+	tr := &Transport{Schedule: []Fault{None, ServerError, MalformedJSON}}
+
+	// the schedule repeats every three requests
+	want := []string{testBody, "service unavailable\n", malformedBody}
+
+	for i := 0; i < 7; i++ {
+		_, body, err := get(context.Background(), tr, srv.URL)
+
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+
+		if w := want[i%len(want)]; string(body) != w {
+			t.Errorf("request %d: body %q, want %q", i, body, w)
+		}
+	}
+
+	if n := tr.Requests(); n != 7 {
+		t.Errorf("Requests() = %d, want %d", n, 7)
+	}
+}
+
+// unwrap returns the error underlying a *url.Error from http.Client
+func unwrap(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+
+	return err
+}
+
+func isContextError(err, want error) bool {
+	return err != nil && unwrap(err) == want
+}
@@ -0,0 +1,80 @@
+package netint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Payload is a raw samples response for a region, as archived by the
+// caller, along with the time it was fetched.
+type Payload struct {
+	Region  string
+	Fetched time.Time
+	Body    []byte
+}
+
+// Replay is a function to feed archived payloads back through the parser,
+// calling 'fn' with the Fetched time of each payload and the *Overview built
+// from it, in order of their Fetched time. Replay stops at the first error,
+// from either parsing or 'fn', and returns it. If 'ctx' is canceled Replay
+// stops and returns ctx.Err().
+//
+// The payloads are spaced out by the time between their Fetched times
+// divided by 'speed', so a speed of 2 replays twice as fast as the data was
+// collected. A speed of zero or less replays everything without waiting.
+//
+// Replay doesn't change the package Clock. Callers that want timestamps
+// derived from it (e.g., by BuildStatusPage) to match the archived data can
+// install their own Clock with WithClock, driven by the time passed to 'fn'.
+func Replay(ctx context.Context, payloads []Payload, speed float64, fn func(fetched time.Time, o *Overview) error) error {
+	p := make([]Payload, len(payloads))
+	copy(p, payloads)
+
+	sort.SliceStable(p, func(i, j int) bool {
+		return p[i].Fetched.Before(p[j].Fetched)
+	})
+
+	for i, pl := range p {
+		if i > 0 && speed > 0 {
+			if err := sleep(ctx, time.Duration(float64(pl.Fetched.Sub(p[i-1].Fetched))/speed)); err != nil {
+				return err
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		o, err := parseOverview(pl.Region, pl.Body)
+
+		if err != nil {
+			return fmt.Errorf("replaying %v payload from %v: %v", pl.Region, pl.Fetched, err)
+		}
+
+		if err := fn(pl.Fetched, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sleep waits for 'd' or until 'ctx' is canceled, returning ctx.Err() in
+// the latter case
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,136 @@
+package netint
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func replayPayloads(tb testing.TB, n int) []Payload {
+	body := loadFixture(tb)
+	start := time.Unix(1418700000, 0)
+
+	var p []Payload
+
+	// archived out of order, Replay sorts them
+	for i := n - 1; i >= 0; i-- {
+		p = append(p, Payload{
+			Region:  "dallas",
+			Fetched: start.Add(time.Duration(i) * time.Minute),
+			Body:    body,
+		})
+	}
+
+	return p
+}
+
+func TestReplay(t *testing.T) {
+	payloads := replayPayloads(t, 3)
+
+	var got []time.Time
+
+	err := Replay(context.Background(), payloads, 0, func(fetched time.Time, o *Overview) error {
+		if o.Name != "dallas" || o.Dallas == nil {
+			t.Errorf("unexpected overview: %+v", o)
+		}
+
+		got = append(got, fetched)
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("fn called %d times, want 3", len(got))
+	}
+
+	for i := 1; i < len(got); i++ {
+		if !got[i].After(got[i-1]) {
+			t.Errorf("payloads replayed out of order: %v", got)
+		}
+	}
+
+	// the caller's slice is left untouched
+	if !payloads[0].Fetched.After(payloads[1].Fetched) {
+		t.Errorf("Replay reordered the caller's payloads")
+	}
+}
+
+func TestReplayCallbackError(t *testing.T) {
+	want := errors.New("stop")
+	calls := 0
+
+	err := Replay(context.Background(), replayPayloads(t, 3), 0, func(time.Time, *Overview) error {
+		calls++
+		return want
+	})
+
+	if err != want || calls != 1 {
+		t.Errorf("got error %v after %d calls, want %v after 1", err, calls, want)
+	}
+}
+
+func TestReplayCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+
+	// a minute between payloads at real-time speed
+	go func() {
+		done <- Replay(ctx, replayPayloads(t, 2), 1, func(time.Time, *Overview) error {
+			cancel()
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("error = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Replay didn't stop when its context was canceled")
+	}
+}
+
+// Replay must leave the package Clock alone so concurrent users of it
+// aren't handed archived times.
+func TestReplayLeavesClock(t *testing.T) {
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			if now().Year() < 2015 {
+				t.Error("package clock reported an archived time during Replay")
+				return
+			}
+		}
+	}()
+
+	err := Replay(context.Background(), replayPayloads(t, 200), 0, func(time.Time, *Overview) error {
+		return nil
+	})
+
+	close(stop)
+	wg.Wait()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,323 @@
+package netint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// NOTE: As has been historically been a pain point with Linode,
+//       these endpoints provide some wonky JSON. Only the timestamp
+//       is in a useful format (numeric). RTT, Loss, and Jitter are all
+//       strings for some reason. Rather than decoding into generic
+//       interface{} values and asserting our way through them, the
+//       payload is scanned by hand straight into a *Sample per region.
+
+var errMalformedResponse = errors.New("malformed samples response")
+
+// used for parsing the JSON response, the key is the
+// region name prefixed with "linode-" (e.g., "linode-dallas")
+type samples map[string]regionSamples
+
+// regionSamples is the most recent sample provided for a single region, or
+// the reason it couldn't be parsed
+type regionSamples struct {
+	sample *Sample
+	err    error
+}
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface. A malformed list
+// of samples for a region is recorded on that region rather than failing the
+// entire response.
+func (s *samples) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	sc := &scanner{data: data}
+
+	if !sc.next('{') {
+		return errMalformedResponse
+	}
+
+	m := make(samples)
+
+	for n := 0; ; n++ {
+		if sc.next('}') {
+			break
+		}
+
+		if n > 0 && !sc.next(',') {
+			return errMalformedResponse
+		}
+
+		k, ok := unquote(sc.value())
+
+		if !ok || !sc.next(':') {
+			return errMalformedResponse
+		}
+
+		sample, err := parseSamples(sc.value())
+		m[k] = regionSamples{sample: sample, err: err}
+	}
+
+	*s = m
+
+	return nil
+}
+
+// parseSamples parses the newest sample out of the list of samples for a
+// region, along with the one before it as its Previous sample. The previous
+// sample is only used for trends, so it's dropped if it's malformed rather
+// than failing the region.
+func parseSamples(data []byte) (*Sample, error) {
+	sc := &scanner{data: data}
+
+	if !sc.next('[') {
+		return nil, errors.New("malformed sample list")
+	}
+
+	if sc.next(']') {
+		return nil, errors.New("malformed sample row")
+	}
+
+	s, err := parseRow(sc.value())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if sc.next(',') {
+		if p, err := parseRow(sc.value()); err == nil {
+			s.Previous = p
+		}
+	}
+
+	return s, nil
+}
+
+// parseRow parses a single sample, which is a list of the form
+// [epoch, "rtt", "loss", "jitter"].
+func parseRow(data []byte) (*Sample, error) {
+	sc := &scanner{data: data}
+
+	if !sc.next('[') {
+		return nil, errors.New("malformed sample row")
+	}
+
+	var row [4][]byte
+	var n int
+
+	for n < len(row) {
+		if sc.next(']') {
+			break
+		}
+
+		if n > 0 && !sc.next(',') {
+			return nil, errors.New("malformed sample row")
+		}
+
+		row[n] = sc.value()
+		n++
+	}
+
+	if n == 0 {
+		return nil, errors.New("malformed sample row")
+	}
+
+	s := &Sample{}
+
+	e, err := parseEpoch(row[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.Epoch = e
+
+	var r, l, j uint32
+
+	// convert the RTT to a uint
+	r, s.HasRTT, err = parseField(row[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	// convert the Loss to a uint
+	l, s.HasLoss, err = parseField(row[2])
+
+	if err != nil {
+		return nil, err
+	}
+
+	// convert the jitter to a uint
+	j, s.HasJitter, err = parseField(row[3])
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.RTT = Milliseconds(r)
+	s.Loss = Percent(l)
+	s.Jitter = Milliseconds(j)
+
+	return s, nil
+}
+
+// parseEpoch parses the numeric UNIX timestamp of a sample row
+func parseEpoch(v []byte) (int64, error) {
+	if e, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+		return e, nil
+	}
+
+	f, err := strconv.ParseFloat(string(v), 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("malformed timestamp: %s", v)
+	}
+
+	return int64(f), nil
+}
+
+// parseField parses one of the stringified numeric fields of a sample row.
+// The bool is false if the field was missing, null, or empty.
+func parseField(v []byte) (uint32, bool, error) {
+	if v == nil || string(v) == "null" {
+		return 0, false, nil
+	}
+
+	str, ok := unquote(v)
+
+	if !ok {
+		return 0, false, fmt.Errorf("malformed value: %s", v)
+	}
+
+	if str == "" {
+		return 0, false, nil
+	}
+
+	n, err := strconv.ParseUint(str, 10, 32)
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint32(n), true, nil
+}
+
+// unquote returns the contents of the JSON string 'v'. The bool is false
+// if 'v' isn't a string.
+func unquote(v []byte) (string, bool) {
+	if len(v) < 2 || v[0] != '"' {
+		return "", false
+	}
+
+	// Linode doesn't escape anything, so take the fast path when we can
+	if bytes.IndexByte(v, '\\') < 0 {
+		return string(v[1 : len(v)-1]), true
+	}
+
+	var str string
+
+	if err := json.Unmarshal(v, &str); err != nil {
+		return "", false
+	}
+
+	return str, true
+}
+
+// scanner walks over a JSON document. It assumes the document is valid,
+// which encoding/json has verified before calling UnmarshalJSON.
+type scanner struct {
+	data []byte
+	pos  int
+}
+
+func (sc *scanner) skipSpace() {
+	for sc.pos < len(sc.data) {
+		switch sc.data[sc.pos] {
+		case ' ', '\t', '\n', '\r':
+			sc.pos++
+		default:
+			return
+		}
+	}
+}
+
+// next skips whitespace and consumes the byte 'c' if it's next. The return
+// value indicates whether 'c' was consumed.
+func (sc *scanner) next(c byte) bool {
+	sc.skipSpace()
+
+	if sc.pos < len(sc.data) && sc.data[sc.pos] == c {
+		sc.pos++
+		return true
+	}
+
+	return false
+}
+
+// value skips whitespace and consumes the next JSON value, returning its
+// raw bytes.
+func (sc *scanner) value() []byte {
+	sc.skipSpace()
+
+	start := sc.pos
+	depth := 0
+
+	for sc.pos < len(sc.data) {
+		switch sc.data[sc.pos] {
+		case '"':
+			sc.skipString()
+
+			if depth == 0 {
+				return sc.data[start:sc.pos]
+			}
+
+			continue
+		case '[', '{':
+			depth++
+		case ']', '}':
+			if depth == 0 {
+				return sc.data[start:sc.pos]
+			}
+
+			depth--
+
+			if depth == 0 {
+				sc.pos++
+				return sc.data[start:sc.pos]
+			}
+		case ',', ':', ' ', '\t', '\n', '\r':
+			if depth == 0 {
+				return sc.data[start:sc.pos]
+			}
+		}
+
+		sc.pos++
+	}
+
+	return sc.data[start:sc.pos]
+}
+
+// skipString consumes the JSON string starting at the current position
+func (sc *scanner) skipString() {
+	// skip the opening quote
+	sc.pos++
+
+	for sc.pos < len(sc.data) {
+		switch sc.data[sc.pos] {
+		case '\\':
+			sc.pos += 2
+			continue
+		case '"':
+			sc.pos++
+			return
+		}
+
+		sc.pos++
+	}
+}
@@ -0,0 +1,429 @@
+package netint
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func loadFixture(tb testing.TB) []byte {
+	body, err := ioutil.ReadFile("testdata/samples.json")
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return body
+}
+
+func TestParseSamples(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want *Sample
+		prev *Sample
+		err  string
+	}{
+		{
+			name: "full row",
+			in:   `[[1418700000,"35","1","2"]]`,
+			want: &Sample{Epoch: 1418700000, RTT: 35, Loss: 1, Jitter: 2, HasRTT: true, HasLoss: true, HasJitter: true},
+		},
+		{
+			name: "second row is the previous sample",
+			in:   `[[1418700000,"35","1","2"],[1418699700,"30","0","1"],[1418699400,"99","99","99"]]`,
+			want: &Sample{Epoch: 1418700000, RTT: 35, Loss: 1, Jitter: 2, HasRTT: true, HasLoss: true, HasJitter: true},
+			prev: &Sample{Epoch: 1418699700, RTT: 30, Jitter: 1, HasRTT: true, HasLoss: true, HasJitter: true},
+		},
+		{
+			name: "malformed previous sample is dropped",
+			in:   `[[1418700000,"35","1","2"],[1418699700,"x","0","1"]]`,
+			want: &Sample{Epoch: 1418700000, RTT: 35, Loss: 1, Jitter: 2, HasRTT: true, HasLoss: true, HasJitter: true},
+		},
+		{
+			name: "short row",
+			in:   `[[1418700000,"35"]]`,
+			want: &Sample{Epoch: 1418700000, RTT: 35, HasRTT: true},
+		},
+		{
+			name: "timestamp only",
+			in:   `[[1418700000]]`,
+			want: &Sample{Epoch: 1418700000},
+		},
+		{
+			name: "null and empty fields",
+			in:   `[[1418700000,null,"","2"]]`,
+			want: &Sample{Epoch: 1418700000, Jitter: 2, HasJitter: true},
+		},
+		{
+			name: "float timestamp",
+			in:   `[[1418700000.0,"35","0","0"]]`,
+			want: &Sample{Epoch: 1418700000, RTT: 35, HasRTT: true, HasLoss: true, HasJitter: true},
+		},
+		{
+			name: "escaped strings",
+			in:   `[[1418700000,"\u0033\u0035","\u0030","0"]]`,
+			want: &Sample{Epoch: 1418700000, RTT: 35, HasRTT: true, HasLoss: true, HasJitter: true},
+		},
+		{
+			name: "whitespace everywhere",
+			in:   " [\n\t[ 1418700000 ,\r\n \"35\" ,\t\"1\" , \"2\" ] \n] ",
+			want: &Sample{Epoch: 1418700000, RTT: 35, Loss: 1, Jitter: 2, HasRTT: true, HasLoss: true, HasJitter: true},
+		},
+		{
+			name: "nested values after the known fields",
+			in:   `[[1418700000,"35","1","2",{"a":["]",[1]]},[9]]]`,
+			want: &Sample{Epoch: 1418700000, RTT: 35, Loss: 1, Jitter: 2, HasRTT: true, HasLoss: true, HasJitter: true},
+		},
+		{
+			name: "numeric field",
+			in:   `[[1418700000,35,"1","2"]]`,
+			err:  "malformed value: 35",
+		},
+		{
+			name: "nested value inside row",
+			in:   `[[1418700000,"35",["1"],"2"]]`,
+			err:  `malformed value: ["1"]`,
+		},
+		{
+			name: "string timestamp",
+			in:   `[["1418700000","35","1","2"]]`,
+			err:  "malformed timestamp",
+		},
+		{
+			name: "non-numeric field",
+			in:   `[[1418700000,"fast","1","2"]]`,
+			err:  "invalid syntax",
+		},
+		{
+			name: "field out of range",
+			in:   `[[1418700000,"4294967296","1","2"]]`,
+			err:  "value out of range",
+		},
+		{
+			name: "empty row",
+			in:   `[[]]`,
+			err:  "malformed sample row",
+		},
+		{
+			name: "empty list",
+			in:   `[]`,
+			err:  "malformed sample row",
+		},
+		{
+			name: "row is not an array",
+			in:   `["1418700000"]`,
+			err:  "malformed sample row",
+		},
+		{
+			name: "object region value",
+			in:   `{"rtt":"35"}`,
+			err:  "malformed sample list",
+		},
+		{
+			name: "string region value",
+			in:   `"35"`,
+			err:  "malformed sample list",
+		},
+		{
+			name: "null region value",
+			in:   `null`,
+			err:  "malformed sample list",
+		},
+	}
+
+	for _, tt := range tests {
+		// the scanner relies on encoding/json having validated the input
+		if !json.Valid([]byte(tt.in)) {
+			t.Fatalf("%v: test input is not valid JSON", tt.name)
+		}
+
+		got, err := parseSamples([]byte(tt.in))
+
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%v: error = %v, want it to contain %q", tt.name, err, tt.err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+			continue
+		}
+
+		prev := got.Previous
+		got.Previous = nil
+
+		if *got != *tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.name, *got, *tt.want)
+		}
+
+		switch {
+		case prev == nil && tt.prev == nil:
+		case prev == nil || tt.prev == nil || *prev != *tt.prev:
+			t.Errorf("%v: got previous %+v, want %+v", tt.name, prev, tt.prev)
+		}
+	}
+}
+
+func TestScannerValue(t *testing.T) {
+	in := ` "a\"],}" , [1,[2,{"b":"}]"}]] ,{"c":null,"d":[]} , -12.5e3,true ,null,""`
+	want := []string{
+		`"a\"],}"`,
+		`[1,[2,{"b":"}]"}]]`,
+		`{"c":null,"d":[]}`,
+		`-12.5e3`,
+		`true`,
+		`null`,
+		`""`,
+	}
+
+	sc := &scanner{data: []byte(in)}
+
+	for i, w := range want {
+		if i > 0 && !sc.next(',') {
+			t.Fatalf("value %d: expected ',' at offset %d", i, sc.pos)
+		}
+
+		if got := string(sc.value()); got != w {
+			t.Errorf("value %d: got %s, want %s", i, got, w)
+		}
+	}
+
+	if sc.skipSpace(); sc.pos != len(sc.data) {
+		t.Errorf("scanner stopped at offset %d, want %d", sc.pos, len(sc.data))
+	}
+}
+
+func TestScannerValueInsideContainers(t *testing.T) {
+	// scalars ending at a closing bracket or brace, or before a colon
+	tests := []struct {
+		in, want string
+	}{
+		{`12]`, `12`},
+		{`null}`, `null`},
+		{`"k":1`, `"k"`},
+		{`"x"]`, `"x"`},
+	}
+
+	for _, tt := range tests {
+		sc := &scanner{data: []byte(tt.in)}
+
+		if got := string(sc.value()); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// The scanner assumes its input is valid JSON. This makes sure invalid
+// documents are rejected by encoding/json before the scanner sees them.
+func TestParseOverviewValidatesJSON(t *testing.T) {
+	tests := []string{
+		``,
+		`{`,
+		`{"linode-dallas":[[1418700000,"1","0"`,
+		`{"linode-dallas":[[1418700000,"1",]]}`,
+		`{"linode-dallas":[[1418700000,"1\"]]}`,
+		`{"linode-dallas" [[1418700000]]}`,
+		`{"linode-dallas":[[1418700000]]}}`,
+	}
+
+	for _, in := range tests {
+		_, err := parseOverview("dallas", []byte(in))
+
+		switch err.(type) {
+		case *json.SyntaxError:
+		default:
+			t.Errorf("%q: error = %#v, want a *json.SyntaxError", in, err)
+		}
+	}
+}
+
+func TestParseOverviewNotObject(t *testing.T) {
+	for _, in := range []string{`[]`, `"samples"`, `12`} {
+		if _, err := parseOverview("dallas", []byte(in)); err != errMalformedResponse {
+			t.Errorf("%q: error = %v, want %v", in, err, errMalformedResponse)
+		}
+	}
+}
+
+func TestParseOverview(t *testing.T) {
+	o, err := parseOverview("dallas", loadFixture(t))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Name != "dallas" {
+		t.Errorf("Name = %q, want %q", o.Name, "dallas")
+	}
+
+	if len(o.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", o.Warnings)
+	}
+
+	want := map[string]Milliseconds{
+		"dallas":  1,
+		"fremont": 44,
+		"atlanta": 20,
+		"newark":  40,
+		"london":  90,
+		"tokyo":   130,
+	}
+
+	for r, rtt := range want {
+		s := o.Sample(r)
+
+		if s == nil {
+			t.Errorf("%v: no sample", r)
+			continue
+		}
+
+		if s.Epoch != 1418700000 || s.RTT != rtt || !s.HasRTT {
+			t.Errorf("%v: got %+v, want RTT %v", r, *s, rtt)
+		}
+	}
+}
+
+func TestParseOverviewWarnings(t *testing.T) {
+	body := `{
+		"linode-dallas": [[1418700000, "1", "0", "0"]],
+		"linode-fremont": [[1418700000, "x", "0", "0"]],
+		"linode-atlanta": [],
+		"linode-newark": {"rtt": "40"},
+		"linode-london": [[1418700000, "90", "1", "0"]],
+		"linode-sydney": [[1418700000, "1", "0", "0"]],
+		"tokyo": [[1418700000, "130", "0", "3"]]
+	}`
+
+	o, err := parseOverview("dallas", []byte(body))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if o.Dallas == nil || o.London == nil {
+		t.Errorf("well-formed samples were dropped: %+v", o)
+	}
+
+	if o.Fremont != nil || o.Atlanta != nil || o.Newark != nil || o.Tokyo != nil {
+		t.Errorf("malformed or missing samples were kept: %+v", o)
+	}
+
+	want := []Warning{
+		{"fremont", `strconv.ParseUint: parsing "x": invalid syntax`},
+		{"atlanta", "malformed sample row"},
+		{"newark", "malformed sample list"},
+		{"tokyo", "no samples provided"},
+		{"sydney", "unknown region"},
+		{"tokyo", "unexpected key"},
+	}
+
+	if len(o.Warnings) != len(want) {
+		t.Fatalf("got warnings %v, want %v", o.Warnings, want)
+	}
+
+	for i, w := range want {
+		if o.Warnings[i] != w {
+			t.Errorf("warning %d: got %v, want %v", i, o.Warnings[i], w)
+		}
+	}
+}
+
+// legacySamples and legacyPullSample are the generic decoding used before
+// samples.UnmarshalJSON, kept to benchmark against
+type legacySamples struct {
+	Dallas  [][]interface{} `json:"linode-dallas"`
+	Fremont [][]interface{} `json:"linode-fremont"`
+	Atlanta [][]interface{} `json:"linode-atlanta"`
+	Newark  [][]interface{} `json:"linode-newark"`
+	London  [][]interface{} `json:"linode-london"`
+	Tokyo   [][]interface{} `json:"linode-tokyo"`
+}
+
+func legacyParseOverview(dc string, body []byte) (*Overview, error) {
+	s := &legacySamples{}
+
+	if err := json.Unmarshal(body, s); err != nil {
+		return nil, err
+	}
+
+	o := &Overview{Name: dc}
+
+	for _, p := range []struct {
+		dst **Sample
+		src [][]interface{}
+	}{
+		{&o.Dallas, s.Dallas},
+		{&o.Fremont, s.Fremont},
+		{&o.Atlanta, s.Atlanta},
+		{&o.Newark, s.Newark},
+		{&o.London, s.London},
+		{&o.Tokyo, s.Tokyo},
+	} {
+		sample, err := legacyPullSample(p.src)
+
+		if err != nil {
+			return nil, err
+		}
+
+		*p.dst = sample
+	}
+
+	return o, nil
+}
+
+func legacyPullSample(i [][]interface{}) (*Sample, error) {
+	r, err := strconv.ParseUint(i[0][1].(string), 10, 32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := strconv.ParseUint(i[0][2].(string), 10, 32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := strconv.ParseUint(i[0][3].(string), 10, 32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sample{
+		Epoch:  int64(i[0][0].(float64)),
+		RTT:    Milliseconds(r),
+		Loss:   Percent(l),
+		Jitter: Milliseconds(j),
+	}, nil
+}
+
+func BenchmarkParseOverview(b *testing.B) {
+	body := loadFixture(b)
+
+	b.Run("before", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := legacyParseOverview("dallas", body); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("after", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := parseOverview("dallas", body); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package netint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the version of the JSON formats written by this package:
+// the on-disk cache entries and the StatusPage payload. It's incremented
+// whenever one of those formats changes, and a migration is added so data
+// written by older versions stays readable. Data written before versioning
+// was introduced has no "schema_version" field and is version 0.
+const SchemaVersion = 1
+
+// migration upgrades a decoded JSON object by a single schema version
+type migration func(doc map[string]json.RawMessage) error
+
+// noMigration is a migration for versions where the format didn't change
+func noMigration(map[string]json.RawMessage) error { return nil }
+
+// cacheMigrations upgrade cache entries, with cacheMigrations[n] upgrading
+// from version n to n+1
+var cacheMigrations = []migration{
+	// 0 -> 1: adds schema_version
+	noMigration,
+}
+
+// statusPageMigrations upgrade StatusPage payloads, with
+// statusPageMigrations[n] upgrading from version n to n+1
+var statusPageMigrations = []migration{
+	// 0 -> 1: adds schema_version
+	noMigration,
+}
+
+// DecodeStatusPage is a function to decode a JSON encoded StatusPage written
+// by this or any older version of this package. Older payloads are migrated
+// to the current SchemaVersion. Returns an error for payloads written by a
+// newer version.
+func DecodeStatusPage(data []byte) (*StatusPage, error) {
+	data, err := migrate(data, statusPageMigrations)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &StatusPage{}
+
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// migrate upgrades the JSON object 'data' to SchemaVersion using 'steps'
+func migrate(data []byte, steps []migration) ([]byte, error) {
+	doc := make(map[string]json.RawMessage)
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	// a JSON null leaves the map nil
+	if doc == nil {
+		return nil, errors.New("document is not a JSON object")
+	}
+
+	var v int
+
+	if raw, ok := doc["schema_version"]; ok {
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("malformed schema_version: %s", raw)
+		}
+	}
+
+	if v == SchemaVersion {
+		return data, nil
+	}
+
+	if v < 0 || v > SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d (this package supports up to %d)", v, SchemaVersion)
+	}
+
+	// every format needs a migration for each version, guard
+	// against one having been missed when SchemaVersion was bumped
+	if len(steps) != SchemaVersion {
+		return nil, fmt.Errorf("have %d migrations for schema version %d", len(steps), SchemaVersion)
+	}
+
+	for ; v < SchemaVersion; v++ {
+		if err := steps[v](doc); err != nil {
+			return nil, fmt.Errorf("migrating from schema version %d: %v", v, err)
+		}
+	}
+
+	doc["schema_version"] = json.RawMessage(fmt.Sprint(SchemaVersion))
+
+	return json.Marshal(doc)
+}
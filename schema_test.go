@@ -0,0 +1,182 @@
+package netint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestFile writes 'data' to 'name' within a temporary directory,
+// returning the path
+func writeTestFile(t *testing.T, name, data string) string {
+	fn := filepath.Join(t.TempDir(), name)
+
+	if err := ioutil.WriteFile(fn, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return fn
+}
+
+func TestDecodeStatusPageNotObject(t *testing.T) {
+	for _, in := range []string{`null`, `[]`, `"page"`, `1`} {
+		p, err := DecodeStatusPage([]byte(in))
+
+		if err == nil {
+			t.Errorf("%s: got %+v, want an error", in, p)
+		}
+	}
+}
+
+func TestReadCacheNull(t *testing.T) {
+	fn := writeTestFile(t, "dallas.json", `null`)
+
+	if body, ok := readCache(fn); ok {
+		t.Errorf("got cache hit %s, want a miss", body)
+	}
+}
+
+func TestMigrationsCoverSchemaVersion(t *testing.T) {
+	for name, steps := range map[string][]migration{
+		"cache":       cacheMigrations,
+		"status page": statusPageMigrations,
+	} {
+		if len(steps) != SchemaVersion {
+			t.Errorf("%v: have %d migrations, want %d", name, len(steps), SchemaVersion)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		steps []migration
+		want  int
+		err   string
+	}{
+		{
+			name:  "unversioned is version 0",
+			in:    `{"generated_at":"2014-12-16T03:20:00Z"}`,
+			steps: statusPageMigrations,
+			want:  SchemaVersion,
+		},
+		{
+			name:  "current version",
+			in:    `{"schema_version":1}`,
+			steps: statusPageMigrations,
+			want:  SchemaVersion,
+		},
+		{
+			name:  "newer version",
+			in:    `{"schema_version":2}`,
+			steps: statusPageMigrations,
+			err:   "unsupported schema version 2",
+		},
+		{
+			name:  "negative version",
+			in:    `{"schema_version":-1}`,
+			steps: statusPageMigrations,
+			err:   "unsupported schema version -1",
+		},
+		{
+			name:  "string version",
+			in:    `{"schema_version":"1"}`,
+			steps: statusPageMigrations,
+			err:   "malformed schema_version",
+		},
+		{
+			name:  "fractional version",
+			in:    `{"schema_version":0.5}`,
+			steps: statusPageMigrations,
+			err:   "malformed schema_version",
+		},
+		{
+			name:  "missing migrations",
+			in:    `{}`,
+			steps: nil,
+			err:   "have 0 migrations",
+		},
+		{
+			name:  "null",
+			in:    `null`,
+			steps: statusPageMigrations,
+			err:   "not a JSON object",
+		},
+	}
+
+	for _, tt := range tests {
+		out, err := migrate([]byte(tt.in), tt.steps)
+
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%v: error = %v, want it to contain %q", tt.name, err, tt.err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+			continue
+		}
+
+		var doc struct {
+			SchemaVersion int `json:"schema_version"`
+		}
+
+		if err := json.Unmarshal(out, &doc); err != nil {
+			t.Errorf("%v: migrated document is invalid: %v", tt.name, err)
+			continue
+		}
+
+		if doc.SchemaVersion != tt.want {
+			t.Errorf("%v: schema_version = %d, want %d", tt.name, doc.SchemaVersion, tt.want)
+		}
+	}
+}
+
+func TestDecodeStatusPageVersion0(t *testing.T) {
+	in := `{"generated_at":"2014-12-16T03:20:00Z","regions":[{"name":"dallas","status":"degraded","degraded_links":2,"outage_links":0}]}`
+
+	p, err := DecodeStatusPage([]byte(in))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", p.SchemaVersion, SchemaVersion)
+	}
+
+	if len(p.Regions) != 1 || p.Regions[0].Name != "dallas" || p.Regions[0].Status != StatusDegraded || p.Regions[0].DegradedLinks != 2 {
+		t.Errorf("regions not preserved: %+v", p.Regions)
+	}
+}
+
+func TestReadCacheVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		hit  bool
+	}{
+		{"version 0", `{"fetched":"%v","body":{}}`, true},
+		{"version 1", `{"schema_version":1,"fetched":"%v","body":{}}`, true},
+		{"newer version", `{"schema_version":2,"fetched":"%v","body":{}}`, false},
+		{"malformed version", `{"schema_version":"x","fetched":"%v","body":{}}`, false},
+	}
+
+	fetched := now().Format(time.RFC3339Nano)
+
+	for _, tt := range tests {
+		fn := writeTestFile(t, "dallas.json", fmt.Sprintf(tt.in, fetched))
+
+		if _, ok := readCache(fn); ok != tt.hit {
+			t.Errorf("%v: cache hit = %v, want %v", tt.name, ok, tt.hit)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package netint
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status is the inferred state of a region for use on a status page.
+type Status string
+
+const (
+	// StatusOperational is a region with no degraded links
+	StatusOperational Status = "operational"
+
+	// StatusDegraded is a region with at least one degraded link
+	StatusDegraded Status = "degraded"
+
+	// StatusOutage is a region with at least half of its links down
+	StatusOutage Status = "outage"
+
+	// StatusUnknown is a region without any samples
+	StatusUnknown Status = "unknown"
+)
+
+// StatusPage is a simple public status page payload, meant to be served
+// as JSON to a static status page. Use DecodeStatusPage to read payloads
+// that may have been written by an older version of this package.
+type StatusPage struct {
+	SchemaVersion int             `json:"schema_version"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Regions       []*RegionStatus `json:"regions"`
+}
+
+// RegionStatus is the state of a single region on a StatusPage.
+type RegionStatus struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+
+	// DegradedLinks and OutageLinks are the number of inbound and
+	// outbound links that are degraded or down.
+	DegradedLinks int `json:"degraded_links"`
+	OutageLinks   int `json:"outage_links"`
+
+	// UpdatedAt is the time of the newest sample for the region's
+	// links. It's omitted if there are no samples.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// StatusPageJSON is a function to get the JSON encoded StatusPage for all
+// regions, using the DefaultThresholds. A region whose overview can't be
+// fetched is still on the page, with its Status inferred from what the
+// other regions see. An error is only returned if no region could be
+// fetched.
+func StatusPageJSON() ([]byte, error) {
+	all, err := availableOverviews()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := BuildStatusPage(all, DefaultThresholds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(p)
+}
+
+// BuildStatusPage is a function to build a StatusPage from the overviews in
+// 'all', keyed by region name as returned by AllOverviews. Each region's
+// Status is inferred from its Summary using the Thresholds 't'.
+func BuildStatusPage(all map[string]*Overview, t Thresholds) (*StatusPage, error) {
+	p := &StatusPage{SchemaVersion: SchemaVersion, GeneratedAt: now().UTC()}
+
+	for _, r := range Regions() {
+		s, err := SummarizeRegion(r, all, t)
+
+		if err != nil {
+			return nil, err
+		}
+
+		p.Regions = append(p.Regions, regionStatus(s))
+	}
+
+	return p, nil
+}
+
+// regionStatus infers the *RegionStatus from the Summary 's'
+func regionStatus(s *Summary) *RegionStatus {
+	rs := &RegionStatus{
+		Name:          s.Name,
+		DegradedLinks: s.Degraded,
+		OutageLinks:   s.Outages,
+	}
+
+	var newest int64
+
+	for _, l := range s.Links {
+		if l.Sample != nil && l.Sample.Epoch > newest {
+			newest = l.Sample.Epoch
+		}
+	}
+
+	switch {
+	case s.Sampled == 0:
+		rs.Status = StatusUnknown
+	case s.Outages*2 >= s.Sampled:
+		rs.Status = StatusOutage
+	case s.Degraded > 0:
+		rs.Status = StatusDegraded
+	default:
+		rs.Status = StatusOperational
+	}
+
+	if s.Sampled > 0 {
+		u := time.Unix(newest, 0).UTC()
+		rs.UpdatedAt = &u
+	}
+
+	return rs
+}
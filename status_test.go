@@ -0,0 +1,239 @@
+package netint
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testOverviews builds an overview for each region, using 'sample' to get
+// the sample for each link. Regions for which 'sample' returns nil for
+// every link are left out.
+func testOverviews(sample func(from, to string) *Sample) map[string]*Overview {
+	all := make(map[string]*Overview)
+
+	for _, from := range Regions() {
+		o := &Overview{Name: from}
+		sampled := false
+
+		for _, to := range Regions() {
+			if s := sample(from, to); s != nil {
+				*o.field(to) = s
+				sampled = true
+			}
+		}
+
+		if sampled {
+			all[from] = o
+		}
+	}
+
+	return all
+}
+
+func healthy() *Sample {
+	return &Sample{Epoch: 1418700000, RTT: 40, HasRTT: true, HasLoss: true, HasJitter: true}
+}
+
+func TestBuildStatusPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		sample   func(from, to string) *Sample
+		status   Status
+		degraded int
+		outages  int
+	}{
+		{
+			name:   "operational",
+			sample: func(from, to string) *Sample { return healthy() },
+			status: StatusOperational,
+		},
+		{
+			name: "one slow link is degraded",
+			sample: func(from, to string) *Sample {
+				s := healthy()
+
+				if from == "newark" && to == "tokyo" {
+					s.RTT = 400
+				}
+
+				return s
+			},
+			status:   StatusDegraded,
+			degraded: 1,
+		},
+		{
+			name: "fewer than half the links down is degraded",
+			sample: func(from, to string) *Sample {
+				s := healthy()
+
+				if from == "newark" && to != "newark" && to != "tokyo" {
+					s.Loss = 60
+				}
+
+				return s
+			},
+			status:   StatusDegraded,
+			degraded: 4,
+			outages:  4,
+		},
+		{
+			name: "half the links down is an outage",
+			sample: func(from, to string) *Sample {
+				s := healthy()
+
+				if from == "newark" && to != "newark" {
+					s.Loss = 60
+				}
+
+				return s
+			},
+			status:   StatusOutage,
+			degraded: 5,
+			outages:  5,
+		},
+		{
+			name: "region that couldn't be fetched is seen through its inbound links",
+			sample: func(from, to string) *Sample {
+				if from == "newark" {
+					return nil
+				}
+
+				return healthy()
+			},
+			status: StatusOperational,
+		},
+		{
+			name:   "no samples is unknown",
+			sample: func(from, to string) *Sample { return nil },
+			status: StatusUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		p, err := BuildStatusPage(testOverviews(tt.sample), DefaultThresholds)
+
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+
+		if len(p.Regions) != len(Regions()) {
+			t.Fatalf("%v: got %d regions, want %d", tt.name, len(p.Regions), len(Regions()))
+		}
+
+		var rs *RegionStatus
+
+		for _, r := range p.Regions {
+			if r.Name == "newark" {
+				rs = r
+			}
+		}
+
+		if rs.Status != tt.status || rs.DegradedLinks != tt.degraded || rs.OutageLinks != tt.outages {
+			t.Errorf("%v: got %v with %d degraded and %d down, want %v with %d and %d",
+				tt.name, rs.Status, rs.DegradedLinks, rs.OutageLinks, tt.status, tt.degraded, tt.outages)
+		}
+
+		if (rs.UpdatedAt == nil) != (tt.status == StatusUnknown) {
+			t.Errorf("%v: UpdatedAt = %v", tt.name, rs.UpdatedAt)
+		}
+	}
+}
+
+// fakeUpstream serves the fixture for every region except those in 'down',
+// which respond with a 503
+type fakeUpstream struct {
+	body     []byte
+	down     map[string]bool
+	requests int
+}
+
+func (f *fakeUpstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests++
+
+	code, body := http.StatusOK, string(f.body)
+
+	if f.down[req.URL.Host] {
+		code, body = http.StatusServiceUnavailable, "down"
+	}
+
+	return &http.Response{
+		Status:     http.StatusText(code),
+		StatusCode: code,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// withUpstream points HTTPClient at a fakeUpstream for the rest of the test
+func withUpstream(t *testing.T, down ...string) *fakeUpstream {
+	f := &fakeUpstream{body: loadFixture(t), down: make(map[string]bool)}
+
+	for _, d := range down {
+		h, err := HostForRegion(d)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f.down[h] = true
+	}
+
+	prev := HTTPClient
+	HTTPClient = &http.Client{Transport: f}
+
+	t.Cleanup(func() { HTTPClient = prev })
+
+	return f
+}
+
+func TestStatusPageJSONRegionDown(t *testing.T) {
+	withUpstream(t, "tokyo")
+
+	data, err := StatusPageJSON()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := DecodeStatusPage(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.Regions) != len(Regions()) {
+		t.Fatalf("got %d regions, want %d", len(p.Regions), len(Regions()))
+	}
+
+	for _, r := range p.Regions {
+		if r.Status == StatusUnknown {
+			t.Errorf("%v: status is unknown", r.Name)
+		}
+	}
+}
+
+func TestStatusPageJSONAllDown(t *testing.T) {
+	withUpstream(t, Regions()...)
+
+	if _, err := StatusPageJSON(); err == nil {
+		t.Error("expected an error when every region is down")
+	}
+}
+
+func TestBuildStatusPageGeneratedAt(t *testing.T) {
+	at := time.Date(2014, 12, 16, 3, 25, 0, 0, time.FixedZone("EST", -5*60*60))
+	withClock(t, &at)
+
+	p, err := BuildStatusPage(nil, DefaultThresholds)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !p.GeneratedAt.Equal(at) || p.GeneratedAt.Location() != time.UTC {
+		t.Errorf("GeneratedAt = %v, want %v in UTC", p.GeneratedAt, at)
+	}
+}
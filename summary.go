@@ -0,0 +1,259 @@
+package netint
+
+import "fmt"
+
+// Thresholds are the limits at which a link between two regions is
+// considered degraded or down. A zero value disables that particular check.
+type Thresholds struct {
+	RTT    Milliseconds
+	Loss   Percent
+	Jitter Milliseconds
+
+	// OutageLoss is the Loss at which a link is considered down
+	OutageLoss Percent
+}
+
+// DefaultThresholds are the Thresholds used by RegionSummary and
+// StatusPageJSON.
+var DefaultThresholds = Thresholds{
+	RTT:        300 * Millisecond,
+	Loss:       1,
+	Jitter:     50 * Millisecond,
+	OutageLoss: TotalLoss / 2,
+}
+
+// Degraded returns whether the Sample 's' exceeds any of the thresholds.
+// Values Linode didn't report aren't considered, and a nil *Sample is never
+// degraded.
+func (t Thresholds) Degraded(s *Sample) bool {
+	if s == nil {
+		return false
+	}
+
+	return (t.RTT > 0 && s.HasRTT && s.RTT >= t.RTT) ||
+		(t.Loss > 0 && s.HasLoss && s.Loss >= t.Loss) ||
+		(t.Jitter > 0 && s.HasJitter && s.Jitter >= t.Jitter)
+}
+
+// Outage returns whether the Sample 's' has lost enough packets for the
+// link to be considered down. A nil *Sample is never an outage.
+func (t Thresholds) Outage(s *Sample) bool {
+	return s != nil && t.OutageLoss > 0 && s.HasLoss && s.Loss >= t.OutageLoss
+}
+
+// Trend is the change from a Sample's Previous sample to the Sample itself.
+type Trend struct {
+	RTT  int64 // milliseconds, positive means slower
+	Loss int64 // percentage points, positive means more loss
+}
+
+// Trend returns the change from the Previous sample to 's'. Returns nil if
+// there's no Previous sample, or if either sample didn't report both RTT
+// and Loss.
+func (s *Sample) Trend() *Trend {
+	if s == nil || s.Previous == nil {
+		return nil
+	}
+
+	p := s.Previous
+
+	if !s.HasRTT || !s.HasLoss || !p.HasRTT || !p.HasLoss {
+		return nil
+	}
+
+	return &Trend{
+		RTT:  int64(s.RTT) - int64(p.RTT),
+		Loss: int64(s.Loss) - int64(p.Loss),
+	}
+}
+
+// Link is the measurement of a single direction between two regions, as
+// seen by the 'From' region. Sample is nil if Linode didn't provide one.
+type Link struct {
+	From   string
+	To     string
+	Sample *Sample
+}
+
+// Summary is the rollup of all inbound and outbound links for a region.
+type Summary struct {
+	Name string
+
+	// Links are the outbound links followed by the inbound links. Links
+	// from a region to itself are not included.
+	Links []*Link
+
+	// Worst is the link with the highest Loss, with ties broken by the
+	// highest RTT. It's nil if none of the links have a sample.
+	Worst *Link
+
+	// MeanRTT is the mean RTT of all links that reported one.
+	MeanRTT Milliseconds
+
+	// Degraded is the number of links exceeding the thresholds.
+	Degraded int
+
+	// Outages is the number of links considered down. These links are
+	// also counted as Degraded.
+	Outages int
+
+	// Sampled is the number of links that have a sample. The other
+	// fields below only account for these links.
+	Sampled int
+
+	// Health is the percentage of links with a sample that aren't
+	// degraded. It's meaningless when Sampled is zero, as nothing is
+	// known about the region.
+	Health Percent
+
+	// Trend is the mean Trend of all links that have one. It's nil if
+	// none of them do.
+	Trend *Trend
+}
+
+// Sample is a function to get the *Sample for the region 'region' (e.g.,
+// "dallas"). Returns nil if the region is unknown or has no sample.
+func (o *Overview) Sample(region string) *Sample {
+	if f := o.field(region); f != nil {
+		return *f
+	}
+
+	return nil
+}
+
+// RegionSummary is a function to get the Summary for a single region, with
+// 'region' being the region name (e.g., "dallas"). It uses the overviews
+// from all regions, with the DefaultThresholds. A region whose overview
+// can't be fetched is left out, so its links have no sample. An error is
+// only returned if no region could be fetched.
+func RegionSummary(region string) (*Summary, error) {
+	all, err := availableOverviews()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return SummarizeRegion(region, all, DefaultThresholds)
+}
+
+// SummarizeRegion is a function to build the Summary for 'region' from the
+// overviews in 'all', keyed by region name as returned by AllOverviews.
+func SummarizeRegion(region string, all map[string]*Overview, t Thresholds) (*Summary, error) {
+	if Abbr(region) == "" {
+		return nil, fmt.Errorf("'%v' is not a valid datacenter\n", region)
+	}
+
+	s := &Summary{Name: region}
+
+	// outbound links, as seen by this region
+	for _, r := range Regions() {
+		if r != region {
+			s.Links = append(s.Links, link(all, region, r))
+		}
+	}
+
+	// inbound links, as seen by the other regions
+	for _, r := range Regions() {
+		if r != region {
+			s.Links = append(s.Links, link(all, r, region))
+		}
+	}
+
+	var rtts, trends int
+	var rttSum uint64
+	var trendSum Trend
+
+	for _, l := range s.Links {
+		if l.Sample == nil {
+			continue
+		}
+
+		s.Sampled++
+
+		if l.Sample.HasRTT {
+			rtts++
+			rttSum += uint64(l.Sample.RTT)
+		}
+
+		if t.Degraded(l.Sample) || t.Outage(l.Sample) {
+			s.Degraded++
+		}
+
+		if t.Outage(l.Sample) {
+			s.Outages++
+		}
+
+		if s.Worst == nil || worse(l.Sample, s.Worst.Sample) {
+			s.Worst = l
+		}
+
+		if tr := l.Sample.Trend(); tr != nil {
+			trends++
+			trendSum.RTT += tr.RTT
+			trendSum.Loss += tr.Loss
+		}
+	}
+
+	if trends > 0 {
+		s.Trend = &Trend{
+			RTT:  trendSum.RTT / int64(trends),
+			Loss: trendSum.Loss / int64(trends),
+		}
+	}
+
+	if rtts > 0 {
+		s.MeanRTT = Milliseconds(rttSum / uint64(rtts))
+	}
+
+	if s.Sampled > 0 {
+		s.Health = ToPercent(float64(s.Sampled-s.Degraded) / float64(s.Sampled))
+	}
+
+	return s, nil
+}
+
+// availableOverviews is like AllOverviews, except that a region whose
+// overview can't be fetched is left out of the map rather than failing the
+// whole call. The error is only returned if every region failed.
+func availableOverviews() (map[string]*Overview, error) {
+	m := make(map[string]*Overview)
+
+	var err error
+
+	for _, d := range Regions() {
+		o, derr := GetOverview(d)
+
+		if derr != nil {
+			err = derr
+			continue
+		}
+
+		m[d] = o
+	}
+
+	if len(m) == 0 {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// link builds the *Link from 'from' to 'to' out of the overviews in 'all'
+func link(all map[string]*Overview, from, to string) *Link {
+	l := &Link{From: from, To: to}
+
+	if o := all[from]; o != nil {
+		l.Sample = o.Sample(to)
+	}
+
+	return l
+}
+
+// worse returns whether 'a' is a worse sample than 'b'
+func worse(a, b *Sample) bool {
+	if a.Loss != b.Loss {
+		return a.Loss > b.Loss
+	}
+
+	return a.RTT > b.RTT
+}
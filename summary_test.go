@@ -0,0 +1,196 @@
+package netint
+
+import "testing"
+
+func TestSummarizeRegionLinks(t *testing.T) {
+	s, err := SummarizeRegion("newark", testOverviews(func(from, to string) *Sample { return healthy() }), DefaultThresholds)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// five other regions, in each direction
+	if len(s.Links) != 10 || s.Sampled != 10 {
+		t.Fatalf("got %d links with %d sampled, want 10", len(s.Links), s.Sampled)
+	}
+
+	for _, l := range s.Links {
+		if l.From == l.To {
+			t.Errorf("self-link %v -> %v included", l.From, l.To)
+		}
+
+		if l.From != "newark" && l.To != "newark" {
+			t.Errorf("unrelated link %v -> %v included", l.From, l.To)
+		}
+	}
+
+	if s.Health != 100 || s.Degraded != 0 {
+		t.Errorf("Health = %v with %d degraded, want 100%% and 0", s.Health, s.Degraded)
+	}
+}
+
+func TestSummarizeRegionUnknown(t *testing.T) {
+	if _, err := SummarizeRegion("sydney", nil, DefaultThresholds); err == nil {
+		t.Error("expected an error for an unknown region")
+	}
+}
+
+func TestSummarizeRegionNoSamples(t *testing.T) {
+	s, err := SummarizeRegion("newark", nil, DefaultThresholds)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Sampled != 0 || s.Health != 0 || s.Worst != nil || s.Trend != nil {
+		t.Errorf("got %+v, want an empty summary", s)
+	}
+}
+
+func TestSummarizeRegionWorst(t *testing.T) {
+	tests := []struct {
+		name     string
+		sample   func(from, to string) *Sample
+		from, to string
+	}{
+		{
+			name: "highest loss wins over highest RTT",
+			sample: func(from, to string) *Sample {
+				s := healthy()
+
+				switch {
+				case from == "newark" && to == "tokyo":
+					s.RTT = 250
+				case from == "london" && to == "newark":
+					s.Loss = 2
+				}
+
+				return s
+			},
+			from: "london", to: "newark",
+		},
+		{
+			name: "ties on loss are broken by RTT",
+			sample: func(from, to string) *Sample {
+				s := healthy()
+				s.Loss = 1
+
+				switch {
+				case from == "newark" && to == "dallas":
+					s.RTT = 90
+				case from == "fremont" && to == "newark":
+					s.RTT = 120
+				}
+
+				return s
+			},
+			from: "fremont", to: "newark",
+		},
+	}
+
+	for _, tt := range tests {
+		s, err := SummarizeRegion("newark", testOverviews(tt.sample), DefaultThresholds)
+
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+
+		if s.Worst == nil || s.Worst.From != tt.from || s.Worst.To != tt.to {
+			t.Errorf("%v: Worst = %+v, want %v -> %v", tt.name, s.Worst, tt.from, tt.to)
+		}
+	}
+}
+
+func TestSummarizeRegionMeanRTT(t *testing.T) {
+	all := testOverviews(func(from, to string) *Sample {
+		s := healthy()
+
+		switch {
+		// not reported, so not part of the mean
+		case from == "newark":
+			s.RTT, s.HasRTT = 0, false
+		case to == "newark":
+			s.RTT = Milliseconds(10 * len(from))
+		}
+
+		return s
+	})
+
+	s, err := SummarizeRegion("newark", all, DefaultThresholds)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dallas, fremont, atlanta, london, tokyo
+	if want := Milliseconds((60 + 70 + 70 + 60 + 50) / 5); s.MeanRTT != want {
+		t.Errorf("MeanRTT = %v, want %v", s.MeanRTT, want)
+	}
+}
+
+func TestSampleTrend(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *Sample
+		want *Trend
+	}{
+		{
+			name: "no previous sample",
+			s:    healthy(),
+		},
+		{
+			name: "getting worse",
+			s: &Sample{RTT: 50, Loss: 3, HasRTT: true, HasLoss: true,
+				Previous: &Sample{RTT: 40, Loss: 1, HasRTT: true, HasLoss: true}},
+			want: &Trend{RTT: 10, Loss: 2},
+		},
+		{
+			name: "getting better",
+			s: &Sample{RTT: 40, HasRTT: true, HasLoss: true,
+				Previous: &Sample{RTT: 45, Loss: 5, HasRTT: true, HasLoss: true}},
+			want: &Trend{RTT: -5, Loss: -5},
+		},
+		{
+			name: "previous didn't report RTT",
+			s: &Sample{RTT: 40, HasRTT: true, HasLoss: true,
+				Previous: &Sample{HasLoss: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := tt.s.Trend()
+
+		switch {
+		case got == nil && tt.want == nil:
+		case got == nil || tt.want == nil || *got != *tt.want:
+			t.Errorf("%v: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeRegionTrend(t *testing.T) {
+	all := testOverviews(func(from, to string) *Sample {
+		s := healthy()
+
+		// only the outbound links have a previous sample
+		if from == "newark" {
+			s.Previous = &Sample{RTT: s.RTT - 10, HasRTT: true, HasLoss: true}
+
+			if to == "tokyo" {
+				s.Previous.Loss = 5
+			}
+		}
+
+		return s
+	})
+
+	s, err := SummarizeRegion("newark", all, DefaultThresholds)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (Trend{RTT: 10, Loss: -1}); s.Trend == nil || *s.Trend != want {
+		t.Errorf("Trend = %+v, want %+v", s.Trend, want)
+	}
+}
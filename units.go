@@ -0,0 +1,84 @@
+package netint
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Milliseconds is the unit Linode reports the RTT and Jitter of a sample in.
+type Milliseconds uint32
+
+// Percent is a percentage from 0 to 100. It's the unit Linode reports the
+// Loss of a sample in, and is also used for scores like Summary.Health.
+type Percent uint32
+
+const (
+	// Millisecond is a single millisecond
+	Millisecond Milliseconds = 1
+
+	// Second is a second in milliseconds
+	Second Milliseconds = 1000
+
+	// NoLoss is a Loss of zero percent
+	NoLoss Percent = 0
+
+	// TotalLoss is a Loss of one hundred percent
+	TotalLoss Percent = 100
+
+	// maxPercent is the largest valid Percent
+	maxPercent Percent = 100
+)
+
+// ToMilliseconds is a function to convert a time.Duration to Milliseconds,
+// rounded to the nearest millisecond. Negative durations become zero.
+func ToMilliseconds(d time.Duration) Milliseconds {
+	if d <= 0 {
+		return 0
+	}
+
+	ms := math.Floor(float64(d)/float64(time.Millisecond) + 0.5)
+
+	if ms > math.MaxUint32 {
+		return math.MaxUint32
+	}
+
+	return Milliseconds(ms)
+}
+
+// ToPercent is a function to convert a ratio in the range [0, 1] to a
+// Percent, rounded to the nearest whole percent. Values outside that range
+// are clamped, and NaN is zero.
+func ToPercent(ratio float64) Percent {
+	switch {
+	case math.IsNaN(ratio), ratio <= 0:
+		return 0
+	case ratio >= 1:
+		return maxPercent
+	default:
+		return Percent(math.Floor(ratio*float64(maxPercent) + 0.5))
+	}
+}
+
+// Duration returns m as a time.Duration.
+func (m Milliseconds) Duration() time.Duration {
+	return time.Duration(m) * time.Millisecond
+}
+
+// Seconds returns m as a floating point number of seconds.
+func (m Milliseconds) Seconds() float64 {
+	return float64(m) / float64(Second)
+}
+
+func (m Milliseconds) String() string {
+	return fmt.Sprintf("%dms", uint32(m))
+}
+
+// Ratio returns p as a ratio in the range [0, 1] (e.g., 25% is 0.25).
+func (p Percent) Ratio() float64 {
+	return float64(p) / float64(maxPercent)
+}
+
+func (p Percent) String() string {
+	return fmt.Sprintf("%d%%", uint32(p))
+}
@@ -0,0 +1,94 @@
+package netint
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestToMilliseconds(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want Milliseconds
+	}{
+		{0, 0},
+		{-time.Second, 0},
+		{time.Millisecond, Millisecond},
+		{1499 * time.Microsecond, Millisecond},
+		{1500 * time.Microsecond, 2 * Millisecond},
+		{time.Second, Second},
+		{100 * 24 * time.Hour, math.MaxUint32},
+	}
+
+	for _, tt := range tests {
+		if got := ToMilliseconds(tt.in); got != tt.want {
+			t.Errorf("ToMilliseconds(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMillisecondsConversions(t *testing.T) {
+	m := 1500 * Millisecond
+
+	if d := m.Duration(); d != 1500*time.Millisecond {
+		t.Errorf("Duration() = %v, want 1.5s", d)
+	}
+
+	if s := m.Seconds(); s != 1.5 {
+		t.Errorf("Seconds() = %v, want 1.5", s)
+	}
+
+	if s := m.String(); s != "1500ms" {
+		t.Errorf("String() = %q, want %q", s, "1500ms")
+	}
+}
+
+func TestToPercent(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want Percent
+	}{
+		{0, 0},
+		{-0.5, 0},
+		{math.Inf(-1), 0},
+		{math.NaN(), 0},
+		{0.004, 0},
+		{0.005, 1},
+		{0.25, 25},
+		{1, 100},
+		{1.5, 100},
+		{math.Inf(1), 100},
+	}
+
+	for _, tt := range tests {
+		if got := ToPercent(tt.in); got != tt.want {
+			t.Errorf("ToPercent(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPercentConversions(t *testing.T) {
+	tests := []struct {
+		in    Percent
+		ratio float64
+		str   string
+	}{
+		{NoLoss, 0, "0%"},
+		{25, 0.25, "25%"},
+		{TotalLoss, 1, "100%"},
+	}
+
+	for _, tt := range tests {
+		if r := tt.in.Ratio(); r != tt.ratio {
+			t.Errorf("%d.Ratio() = %v, want %v", uint32(tt.in), r, tt.ratio)
+		}
+
+		if s := tt.in.String(); s != tt.str {
+			t.Errorf("%d.String() = %q, want %q", uint32(tt.in), s, tt.str)
+		}
+
+		if p := ToPercent(tt.in.Ratio()); p != tt.in {
+			t.Errorf("ToPercent(%v.Ratio()) = %v", tt.in, p)
+		}
+	}
+}